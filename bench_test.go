@@ -0,0 +1,67 @@
+// Copyright 2026 Olivier Mengué
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package regexpstruct_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/dolmen-go/regexpstruct"
+)
+
+const (
+	benchExpr = `^(?P<date>\S+) (?P<level>[A-Z]+) \[(?P<module>\w+)\] (?P<msg>.*)$`
+	benchLine = `2023-10-01T12:34:56Z INFO [server] listening on :8080`
+)
+
+type BenchLog struct {
+	Date   string `rx:"date"`
+	Level  string `rx:"level"`
+	Module string `rx:"module"`
+	Msg    string `rx:"msg"`
+}
+
+// BenchmarkFindStringStruct compares FindStringStruct on a flat string-only
+// struct with hand-written FindStringSubmatchIndex and assignments.
+// See README.md for results.
+func BenchmarkFindStringStruct(b *testing.B) {
+	b.Run("handwritten", func(b *testing.B) {
+		re := regexp.MustCompile(benchExpr)
+		iDate, iLevel, iModule, iMsg := re.SubexpIndex("date"), re.SubexpIndex("level"), re.SubexpIndex("module"), re.SubexpIndex("msg")
+		var l BenchLog
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			m := re.FindStringSubmatchIndex(benchLine)
+			if m == nil {
+				b.Fatal("no match")
+			}
+			l.Date = benchLine[m[2*iDate]:m[2*iDate+1]]
+			l.Level = benchLine[m[2*iLevel]:m[2*iLevel+1]]
+			l.Module = benchLine[m[2*iModule]:m[2*iModule+1]]
+			l.Msg = benchLine[m[2*iMsg]:m[2*iMsg+1]]
+		}
+	})
+
+	b.Run("FindStringStruct", func(b *testing.B) {
+		re := regexpstruct.MustCompile[BenchLog](benchExpr, "rx")
+		var l BenchLog
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if !re.FindStringStruct(benchLine, &l) {
+				b.Fatal("no match")
+			}
+		}
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 Olivier Mengué
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package regexpstruct
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+)
+
+// DynamicRegexp is like [Regexp], but for a struct type known only at runtime.
+//
+// All the [regexp.Regexp] methods are available.
+type DynamicRegexp struct {
+	re
+	binding
+}
+
+// CompileDynamic is like [Compile] for the struct type typ, but as typ is
+// known only at runtime, an invalid typ or structTag is reported as an error
+// instead of a panic.
+func CompileDynamic(expr string, structTag string, typ reflect.Type, opts ...Option) (*DynamicRegexp, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	b, err := bind(re, typ, structTag, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &DynamicRegexp{
+		re:      re,
+		binding: b,
+	}, nil
+}
+
+// MustCompileDynamic is like CompileDynamic but panics in case of error.
+func MustCompileDynamic(expr string, structTag string, typ reflect.Type, opts ...Option) *DynamicRegexp {
+	re, err := CompileDynamic(expr, structTag, typ, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return re
+}
+
+// Type returns the struct type into which submatches are stored.
+func (re *DynamicRegexp) Type() reflect.Type {
+	return re.typ
+}
+
+// FindStringInto is like [Regexp.FindStringStructErr]. target must be a
+// non-nil pointer to a value of the struct type given to [CompileDynamic].
+func (re *DynamicRegexp) FindStringInto(s string, target any) (bool, error) {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Type().Elem() != re.typ {
+		return false, fmt.Errorf("target must be a non-nil *%s, got %T", re.typ, target)
+	}
+	return re.binding.findString(re.re, s, v)
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 Olivier Mengué
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package regexpstruct
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// FindAllLinesStruct applies [Regexp.FindStringStruct] to each of lines,
+// using up to parallelism goroutines, and returns the values of the lines
+// that match, in the order of lines, with the index in lines of each value.
+// If parallelism <= 0, [runtime.GOMAXPROCS] goroutines are used.
+//
+// Lines that don't match are skipped, as well as lines that match but with a
+// submatch that can't be converted to its field type.
+func (re *Regexp[T]) FindAllLinesStruct(lines []string, parallelism int) (values []T, index []int) {
+	if parallelism <= 0 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	if parallelism > len(lines) {
+		parallelism = len(lines)
+	}
+
+	r := make([]T, len(lines))
+	matched := make([]bool, len(lines))
+
+	// Since Go 1.12 a *regexp.Regexp can be shared by goroutines without
+	// contention, so workers don't need a Copy.
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(parallelism)
+	for w := 0; w < parallelism; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(lines) {
+					return
+				}
+				matched[i] = re.FindStringStruct(lines[i], &r[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, ok := range matched {
+		if ok {
+			index = append(index, i)
+		}
+	}
+	if index == nil {
+		return nil, nil
+	}
+	// Copy, to not retain the array of len(lines) values
+	values = make([]T, len(index))
+	for j, i := range index {
+		values[j] = r[i]
+	}
+	return values, index
+}
@@ -18,15 +18,56 @@
 // methods that store capture results into a given struct, matching struct tags
 // with captures names.
 //
+// [Rebind] derives a [Regexp] for another struct type from an existing [Regexp],
+// sharing the compiled expression.
+//
+// [DynamicRegexp], built with [CompileDynamic], is the non-generic variant of
+// [Regexp] for struct types known only at runtime. It exposes
+// [DynamicRegexp.FindStringInto].
+//
 // The following methods are exposed:
+//   - [Regexp.Schema]: describes the bound fields, for introspection
 //   - [Regexp.FindStringStruct]: similar to [regexp.FindStringSubmatch]
+//   - [Regexp.FindStringStructErr]: like FindStringStruct, but reports conversion errors
 //   - [Regexp.FindAllStringStruct]: similar to [regexp.FindAllStringSubmatch]
+//   - [Regexp.FindAllLinesStruct]: FindStringStruct applied concurrently to a slice of lines
+//
+// Submatches are stored into fields of any type whose pointer implements
+// (checked in that order):
+//   - [encoding.TextUnmarshaler] (such as [time.Time], [math/big.Int], [math/big.Float], [math/big.Rat])
+//   - interface{ Set(string) error } (such as [flag.Value])
+//   - [fmt.Scanner]: the whole submatch must be consumed
+//
+// or else of kind string, bool (see [strconv.ParseBool]), integer (see
+// [strconv.ParseInt], [strconv.ParseUint]) or float (see [strconv.ParseFloat]).
+//
+// The struct tag may list alternate capture names separated by '|', such as
+// `rx:"msg|message|text"`: the field is bound to any of those names that is a
+// submatch of the regexp. This allows to use the same struct with variants of
+// a pattern.
+//
+// The capture name in the struct tag may be followed by comma-separated options
+// (unknown options are ignored, so tags of other packages such as "json" can
+// be reused):
+//   - base=N: the base (0, or 2 to 36) for parsing integer fields (default 10).
+//     With base=0 the base is implied by the prefix: "0x", "0o", "0b" or "0".
+//   - comma: remove commas (thousands separators) from the text before parsing
+//     integer or float fields.
+//
+// Pointer fields are allocated as needed. A submatch which didn't participate
+// in the match stores the zero value into its field, except for pointer fields
+// which are left untouched.
 package regexpstruct
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 // re is defined only for private embedding
@@ -37,14 +78,72 @@ type re = *regexp.Regexp
 // All the [regexp.Regexp] methods are available.
 type Regexp[T any] struct {
 	re
+	binding
+}
+
+// binding is the plan for storing the submatches of a regexp into a struct.
+type binding struct {
+	typ      reflect.Type
 	captures []capture
+	reset    bool
+}
+
+// Option is an option for [Compile].
+type Option func(*options)
+
+type options struct {
+	reset  bool
+	folded bool
+}
+
+// WithReset makes [Regexp.FindStringStruct] reset the target to its zero
+// value before storing submatches. This avoids leaking values from a previous
+// match into pointer fields whose submatches didn't participate when the same
+// target is reused in a loop.
+func WithReset() Option {
+	return func(o *options) {
+		o.reset = true
+	}
+}
+
+// WithFoldedNames makes [Compile] match submatches names with struct tags
+// case-insensitively and with '-' and '_' treated as equivalent.
+//
+// If two struct tags are then equivalent, [Compile] and [Rebind] panic and
+// [CompileDynamic] returns an error.
+func WithFoldedNames() Option {
+	return func(o *options) {
+		o.folded = true
+	}
+}
+
+// foldName normalizes name for [WithFoldedNames].
+func foldName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "-", "_"))
 }
 
 type capture struct {
-	index int
-	get   func(reflect.Value) reflect.Value
+	index   int
+	name    string
+	aliases []int // indexes of the other submatches bound to the same field
+	field
+}
+
+// field describes how to reach a struct field from the root value and how to
+// store text into it.
+type field struct {
+	get func(reflect.Value) reflect.Value
+	set setFunc // nil if the type is not supported
+	typ reflect.Type
+
+	path       []string // names of the Go fields from the root value
+	fieldIndex []int    // index sequence of the Go fields from the root value
+	exported   bool     // settable with reflect, through exported fields
+	opts       tagOptions
 }
 
+type setFunc func(v reflect.Value, s string) error
+
 // Compile wraps [regexp.Compile] to extend [regexp.Regexp] as [Regexp].
 //
 // Type T must be a struct type with struct tags structTag that must match
@@ -54,23 +153,72 @@ type capture struct {
 // See also [regexp.Regexp.Expand] for capture naming constraints.
 //
 // Recommended tag names: "re", "rx", or "regexp".
-func Compile[T any](expr string, structTag string) (*Regexp[T], error) {
-	if structTag == "" {
-		panic("invalid tag name")
-	}
-	if reflect.TypeOf((*T)(nil)).Elem().Kind() != reflect.Struct {
-		panic("T must be a struct type")
-	}
+func Compile[T any](expr string, structTag string, opts ...Option) (*Regexp[T], error) {
 	re, err := regexp.Compile(expr)
 	if err != nil {
 		return nil, err
 	}
+	b, err := bind(re, reflect.TypeOf((*T)(nil)).Elem(), structTag, opts)
+	if err != nil {
+		panic(err)
+	}
+	return &Regexp[T]{
+		re:      re,
+		binding: b,
+	}, nil
+}
+
+// Rebind returns a [Regexp] that shares the compiled regexp of re but stores
+// submatches into struct type U. This avoids compiling again the same
+// expression for different struct types.
+//
+// Type U, structTag and opts have the same constraints as in [Compile]: the
+// options of re are not inherited.
+func Rebind[U, T any](re *Regexp[T], structTag string, opts ...Option) *Regexp[U] {
+	b, err := bind(re.re, reflect.TypeOf((*U)(nil)).Elem(), structTag, opts)
+	if err != nil {
+		panic(err)
+	}
+	return &Regexp[U]{
+		re:      re.re,
+		binding: b,
+	}
+}
+
+// bind matches the submatches names of re with the struct tags of type t.
+// The error reports a misuse of the API: [Compile] and [Rebind] panic with it.
+func bind(re *regexp.Regexp, t reflect.Type, structTag string, opts []Option) (binding, error) {
+	if structTag == "" {
+		return binding{}, errors.New("invalid tag name")
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return binding{}, fmt.Errorf("%v is not a struct type", t)
+	}
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
 	matchesNames := re.SubexpNames()
 
-	fields := extractFields(reflect.TypeOf((*T)(nil)).Elem(), structTag)
+	fields, err := extractFields(t, structTag)
+	if err != nil {
+		return binding{}, err
+	}
 	if len(fields) == 0 {
-		var zeroT T
-		panic(fmt.Errorf("type %T has no fields with stuct tag %q", zeroT, structTag))
+		return binding{}, fmt.Errorf("type %s has no fields with stuct tag %q", t, structTag)
+	}
+	if o.folded {
+		folded := make(map[string]field, len(fields))
+		names := make(map[string]string, len(fields))
+		for name, f := range fields {
+			key := foldName(name)
+			if other, dup := names[key]; dup && !slices.Equal(fields[other].path, f.path) {
+				return binding{}, fmt.Errorf("type %s: struct tags %q and %q conflict with folded names", t, other, name)
+			}
+			names[key] = name
+			folded[key] = f
+		}
+		fields = folded
 	}
 
 	captures := make([]capture, 0, len(matchesNames))
@@ -79,22 +227,42 @@ func Compile[T any](expr string, structTag string) (*Regexp[T], error) {
 		if name == "" {
 			continue
 		}
-		if get := fields[name]; get != nil {
-			captures = append(captures, capture{index: i, get: get})
+		key := name
+		if o.folded {
+			key = foldName(name)
+		}
+		if f, ok := fields[key]; ok {
+			if f.set == nil {
+				return binding{}, fmt.Errorf("capture %q: unsupported field type %s", name, f.typ)
+			}
+			if !f.exported {
+				return binding{}, fmt.Errorf("capture %q: field %s is not exported", name, strings.Join(f.path, "."))
+			}
+			captures = append(captures, capture{index: i, name: name, field: f})
 		}
 	}
 
-	return &Regexp[T]{
-		re:       re,
+	// Link submatches bound to the same field (see aliases in struct tags)
+	for i := range captures {
+		for j := range captures {
+			if i != j && slices.Equal(captures[i].path, captures[j].path) {
+				captures[i].aliases = append(captures[i].aliases, captures[j].index)
+			}
+		}
+	}
+
+	return binding{
+		typ:      t,
 		captures: captures,
+		reset:    o.reset,
 	}, nil
 }
 
 // MustCompile is like Compile but panics if the expression cannot be parsed.
 // It simplifies safe initialization of global variables holding compiled
 // regular expressions.
-func MustCompile[T any](expr string, structTag string) *Regexp[T] {
-	re, err := Compile[T](expr, structTag)
+func MustCompile[T any](expr string, structTag string, opts ...Option) *Regexp[T] {
+	re, err := Compile[T](expr, structTag, opts...)
 	if err != nil {
 		panic(err)
 	}
@@ -105,12 +273,187 @@ var (
 	typeEmptyStruct     = reflect.TypeOf(struct{}{})
 	typeSetter          = reflect.TypeOf((*interface{ Set(string) error })(nil)).Elem()
 	typeTextUnmarshaler = reflect.TypeOf((*interface{ UnmarshalText([]byte) error })(nil)).Elem()
+	typeScanner         = reflect.TypeOf((*fmt.Scanner)(nil)).Elem()
 )
 
-func extractFields(t reflect.Type, tagName string) (fields map[string]func(reflect.Value) reflect.Value) {
+// tagOptions are the options that follow the capture name in a struct tag.
+type tagOptions struct {
+	base  int
+	comma bool
+}
+
+// parseTag splits a struct tag into the capture name and its options.
+// Unknown options are ignored (as [encoding/json] does), so that tags shared
+// with other packages can be used.
+func parseTag(tag string) (name string, opts tagOptions, err error) {
+	name, rest, _ := strings.Cut(tag, ",")
+	opts.base = 10
+	known := false
+	for rest != "" {
+		var opt string
+		opt, rest, _ = strings.Cut(rest, ",")
+		switch key, value, _ := strings.Cut(opt, "="); key {
+		case "base":
+			opts.base, err = strconv.Atoi(value)
+			if err != nil || opts.base == 1 || opts.base < 0 || opts.base > 36 {
+				return "", opts, fmt.Errorf("invalid base %q", value)
+			}
+			known = true
+		case "comma":
+			opts.comma = true
+			known = true
+		}
+	}
+	if name == "" && known {
+		return "", opts, errors.New("missing capture name")
+	}
+	return
+}
+
+// converter returns the function that stores text into a value of type t, or
+// nil if type t is not supported.
+//
+// base is used only for integer kinds: converter returns nil if base is not 10
+// and type t is not parsed as an integer.
+func converter(t reflect.Type, base int) setFunc {
+	pt := reflect.PointerTo(t)
+	switch {
+	case base != 10 && !isInteger(t) && t.Kind() != reflect.Ptr:
+		return nil
+	case pt.Implements(typeTextUnmarshaler):
+		return func(v reflect.Value, s string) error {
+			return v.Addr().Interface().(interface{ UnmarshalText([]byte) error }).UnmarshalText([]byte(s))
+		}
+	case pt.Implements(typeSetter):
+		return func(v reflect.Value, s string) error {
+			return v.Addr().Interface().(interface{ Set(string) error }).Set(s)
+		}
+	case pt.Implements(typeScanner):
+		return func(v reflect.Value, s string) error {
+			r := strings.NewReader(s)
+			if _, err := fmt.Fscan(r, v.Addr().Interface()); err != nil {
+				return err
+			}
+			// The whole text must be consumed
+			var extra string
+			if _, err := fmt.Fscan(r, &extra); err != io.EOF {
+				return fmt.Errorf("unexpected %q after value", extra)
+			}
+			return nil
+		}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return func(v reflect.Value, s string) error {
+			v.SetString(s)
+			return nil
+		}
+	case reflect.Bool:
+		return func(v reflect.Value, s string) error {
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return err
+			}
+			v.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(v reflect.Value, s string) error {
+			n, err := strconv.ParseInt(s, base, t.Bits())
+			if err != nil {
+				return err
+			}
+			v.SetInt(n)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(v reflect.Value, s string) error {
+			n, err := strconv.ParseUint(s, base, t.Bits())
+			if err != nil {
+				return err
+			}
+			v.SetUint(n)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		return func(v reflect.Value, s string) error {
+			f, err := strconv.ParseFloat(s, t.Bits())
+			if err != nil {
+				return err
+			}
+			v.SetFloat(f)
+			return nil
+		}
+	case reflect.Ptr:
+		elemType := t.Elem()
+		elem := converter(elemType, base)
+		if elem == nil {
+			return nil
+		}
+		return func(v reflect.Value, s string) error {
+			if v.IsNil() {
+				v.Set(reflect.New(elemType))
+			}
+			return elem(v.Elem(), s)
+		}
+	}
+	return nil
+}
+
+// hasTextMethods reports if converter uses methods of type t rather than its kind.
+func hasTextMethods(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return pt.Implements(typeTextUnmarshaler) || pt.Implements(typeSetter) || pt.Implements(typeScanner)
+}
+
+// isInteger reports if values of type t are parsed by converter as integers.
+func isInteger(t reflect.Type) bool {
+	if hasTextMethods(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// isNumeric reports if values of type t, or of the type it points to, are
+// parsed by converter as numbers.
+func isNumeric(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if isInteger(t) {
+		return true
+	}
+	return !hasTextMethods(t) && (t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64)
+}
+
+// withoutCommas wraps set to remove commas from the text.
+func withoutCommas(set setFunc) setFunc {
+	return func(v reflect.Value, s string) error {
+		return set(v, strings.ReplaceAll(s, ",", ""))
+	}
+}
+
+// isNested reports if a tagged field of type t (with converter set) is a
+// struct (or a pointer to a struct) whose own tagged fields must be bound.
+func isNested(t reflect.Type, set setFunc) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && (t.Name() == "" || set == nil)
+}
+
+func extractFields(t reflect.Type, tagName string) (fields map[string]field, err error) {
 	switch t.Kind() {
 	case reflect.Ptr:
-		fields = extractFields(t.Elem(), tagName)
+		fields, err = extractFields(t.Elem(), tagName)
+		if err != nil {
+			return nil, err
+		}
 		wrapFields(fields,
 			func(v reflect.Value) reflect.Value {
 				if v.IsNil() {
@@ -122,38 +465,76 @@ func extractFields(t reflect.Type, tagName string) (fields map[string]func(refle
 		for i := 0; i < t.NumField(); i++ {
 			index := i
 			f := t.Field(index)
-			if tag, ok := f.Tag.Lookup(tagName); ok && tag != "" {
+			tag, opts, err := parseTag(f.Tag.Get(tagName))
+			if err != nil {
+				return nil, fmt.Errorf("field %s: struct tag %q: %w", f.Name, tagName, err)
+			}
+			if tag != "" {
 				if fields == nil {
-					fields = make(map[string]func(reflect.Value) reflect.Value)
+					fields = make(map[string]field)
 				}
 
-				/*
-					typeName := f.Type.Name()
-					isSetter := f.Type.AssignableTo(typeSetter)
-					isUnmarshaler := f.Type.AssignableTo(typeTextUnmarshaler)
-					_, _, _ = typeName, isSetter, isUnmarshaler
-				*/
-
-				isStruct := f.Type.Kind() == reflect.Struct &&
-					(f.Type.Name() == "" ||
-						(!f.Type.AssignableTo(typeSetter) && !f.Type.AssignableTo(typeTextUnmarshaler)))
-				if isStruct {
-					fields2 := extractFields(f.Type, tagName)
-					for name, g := range fields2 {
-						getter := g
-						fields[tag+"__"+name] = func(v reflect.Value) reflect.Value { return getter(v.Field(index)) }
+				set := converter(f.Type, opts.base)
+				if set == nil && opts.base != 10 && converter(f.Type, 10) != nil {
+					return nil, fmt.Errorf("field %s: option base: %s is not an integer type", f.Name, f.Type)
+				}
+				if opts.comma && !isNumeric(f.Type) {
+					return nil, fmt.Errorf("field %s: option comma: %s is not a numeric type", f.Name, f.Type)
+				}
+				if isNested(f.Type, set) {
+					if opts != (tagOptions{base: 10}) {
+						return nil, fmt.Errorf("field %s: options not allowed on struct", f.Name)
+					}
+					fields2, err := extractFields(f.Type, tagName)
+					if err != nil {
+						return nil, err
+					}
+					for name, f2 := range fields2 {
+						getter := f2.get
+						f2.get = func(v reflect.Value) reflect.Value { return getter(v.Field(index)) }
+						f2.path = append([]string{f.Name}, f2.path...)
+						f2.fieldIndex = append([]int{index}, f2.fieldIndex...)
+						f2.exported = f2.exported && f.IsExported()
+						for _, alias := range strings.Split(tag, "|") {
+							fields[alias+"__"+name] = f2
+						}
 					}
 				} else {
-					fields[tag] = func(v reflect.Value) reflect.Value { return v.Field(index) }
+					if opts.comma && set != nil {
+						set = withoutCommas(set)
+					}
+					f2 := field{
+						get:        func(v reflect.Value) reflect.Value { return v.Field(index) },
+						set:        set,
+						typ:        f.Type,
+						path:       []string{f.Name},
+						fieldIndex: []int{index},
+						exported:   f.IsExported(),
+						opts:       opts,
+					}
+					for _, alias := range strings.Split(tag, "|") {
+						fields[alias] = f2
+					}
 				}
 			} else if f.Anonymous { // recurse into embedded struct
-				fields2 := extractFields(f.Type, tagName)
+				fields2, err := extractFields(f.Type, tagName)
+				if err != nil {
+					return nil, err
+				}
+				for name, f2 := range fields2 {
+					f2.path = append([]string{f.Name}, f2.path...)
+					f2.fieldIndex = append([]int{index}, f2.fieldIndex...)
+					// Fields promoted from an unexported embedded struct are
+					// settable, but not through an unexported pointer.
+					f2.exported = f2.exported && (f.IsExported() || f.Type.Kind() != reflect.Ptr)
+					fields2[name] = f2
+				}
 				wrapFields(fields2, func(v reflect.Value) reflect.Value { return v.Field(index) })
 				if fields == nil {
 					fields = fields2
 				} else {
-					for name, getter := range fields2 {
-						fields[name] = getter
+					for name, f2 := range fields2 {
+						fields[name] = f2
 					}
 				}
 			}
@@ -163,43 +544,101 @@ func extractFields(t reflect.Type, tagName string) (fields map[string]func(refle
 	return
 }
 
-func wrapFields(fields map[string]func(reflect.Value) reflect.Value, w func(reflect.Value) reflect.Value) {
-	for name := range fields {
-		inner := fields[name]
-		fields[name] = func(v reflect.Value) reflect.Value { return inner(w(v)) }
+func wrapFields(fields map[string]field, w func(reflect.Value) reflect.Value) {
+	for name, f := range fields {
+		inner := f.get
+		f.get = func(v reflect.Value) reflect.Value { return inner(w(v)) }
+		fields[name] = f
+	}
+}
+
+// aliasMatched reports if any of the submatches aliases participated in the
+// match.
+func aliasMatched(matches []int, aliases []int) bool {
+	for _, i := range aliases {
+		if matches[2*i] >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// deserialize stores into target the submatches of s located by matches (as
+// returned by [regexp.Regexp.FindStringSubmatchIndex]).
+func deserialize(s string, matches []int, captures []capture, target reflect.Value) error {
+	for _, c := range captures {
+		start := matches[2*c.index]
+		if start < 0 { // submatch didn't participate in the match
+			if c.typ.Kind() != reflect.Ptr && !aliasMatched(matches, c.aliases) {
+				// Don't allocate pointers on the path: a nil struct pointer
+				// already means no value.
+				if v, err := target.FieldByIndexErr(c.fieldIndex); err == nil {
+					v.SetZero()
+				}
+			}
+			continue
+		}
+		if err := c.set(c.get(target), s[start:matches[2*c.index+1]]); err != nil {
+			return fmt.Errorf("capture %q: %w", c.name, err)
+		}
 	}
+	return nil
 }
 
-func deserialize(matches []string, captures []capture, target reflect.Value) {
-	for _, m := range captures {
-		m.get(target).SetString(matches[m.index])
+// findString stores into the struct pointed by target the submatches of the
+// leftmost match of re in s.
+func (b *binding) findString(re *regexp.Regexp, s string, target reflect.Value) (bool, error) {
+	matches := re.FindStringSubmatchIndex(s)
+	if matches == nil {
+		return false, nil
+	}
+	if b.reset {
+		target.Elem().SetZero()
 	}
+	if err := deserialize(s, matches, b.captures, target.Elem()); err != nil {
+		return true, err
+	}
+	return true, nil
 }
 
 // FindStringStruct wraps [regexp.Regexp.FindStringSubmatch] to store submatches into
 // a struct type value using struct tags.
+//
+// A submatch that can't be converted to its field type makes the match fail.
+// Use [Regexp.FindStringStructErr] to get the conversion error.
 func (re *Regexp[T]) FindStringStruct(s string, target *T) bool {
-	matches := re.re.FindStringSubmatch(s)
-	if matches == nil {
-		return false
-	}
-	deserialize(matches, re.captures, reflect.ValueOf(target).Elem())
-	return true
+	ok, err := re.FindStringStructErr(s, target)
+	return ok && err == nil
 }
 
-// FindAllStringStruct wraps [regexp.Regexp.FinfAllStringSubmatch] to store repeated
+// FindStringStructErr is like [Regexp.FindStringStruct] but returns the error
+// of the conversion of a submatch to its field type.
+//
+// See [WithReset] to reset target before storing submatches.
+// In case of error, target may be partially filled.
+func (re *Regexp[T]) FindStringStructErr(s string, target *T) (bool, error) {
+	return re.binding.findString(re.re, s, reflect.ValueOf(target))
+}
+
+// FindAllStringStruct wraps [regexp.Regexp.FindAllStringSubmatch] to store repeated
 // captures a into a []T.
+//
+// Matches with a submatch that can't be converted to its field type are skipped.
 func (re *Regexp[T]) FindAllStringStruct(s string, n int) []T {
-	matches := re.re.FindAllStringSubmatch(s, n)
+	matches := re.re.FindAllStringSubmatchIndex(s, n)
 	if matches == nil {
 		return nil
 	}
-	nbMatches := len(matches)
 
-	r := make([]T, nbMatches)
+	r := make([]T, len(matches))
 	v := reflect.ValueOf(r)
-	for i := 0; i < nbMatches; i++ {
-		deserialize(matches[i], re.captures, v.Index(i))
+	j := 0
+	for i := range matches {
+		if err := deserialize(s, matches[i], re.captures, v.Index(j)); err != nil {
+			v.Index(j).SetZero()
+			continue
+		}
+		j++
 	}
-	return r
+	return r[:j]
 }
@@ -15,12 +15,31 @@
 package regexpstruct_test
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/dolmen-go/regexpstruct"
 )
 
+// mustPanic checks that f panics.
+func mustPanic(t *testing.T, f func()) {
+	t.Helper()
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Error("panic expected")
+		}
+		t.Log(r)
+	}()
+	f()
+}
+
 func Example() {
 	type pair struct {
 		K string `rx:"k"`
@@ -114,3 +133,685 @@ func TestEmbedded(t *testing.T) {
 		t.Error("mismatch between FindStringStruct and FindAllStringStruct")
 	}
 }
+
+type upper string
+
+func (u *upper) Set(s string) error {
+	*u = upper(strings.ToUpper(s))
+	return nil
+}
+
+// point implements only fmt.Scanner.
+type point struct {
+	X, Y int
+}
+
+func (p *point) Scan(state fmt.ScanState, verb rune) error {
+	tok, err := state.Token(true, nil)
+	if err != nil {
+		return err
+	}
+	x, y, ok := strings.Cut(string(tok), ":")
+	if !ok {
+		return fmt.Errorf("invalid point %q", tok)
+	}
+	if p.X, err = strconv.Atoi(x); err != nil {
+		return err
+	}
+	p.Y, err = strconv.Atoi(y)
+	return err
+}
+
+func TestScanner(t *testing.T) {
+	type record struct {
+		Pos  point  `rx:"pos"`
+		Pos2 *point `rx:"pos2"`
+	}
+
+	re := regexpstruct.MustCompile[record](`^(?P<pos>.*?)(?: / (?P<pos2>.*))?$`, "rx")
+
+	var r record
+	ok, err := re.FindStringStructErr("1:2 / 3:4", &r)
+	if !ok || err != nil {
+		t.Fatalf("ok=%v, err=%v", ok, err)
+	}
+	if r.Pos != (point{1, 2}) || r.Pos2 == nil || *r.Pos2 != (point{3, 4}) {
+		t.Errorf("unexpected: %+v", r)
+	}
+
+	// Unconsumed input
+	ok, err = re.FindStringStructErr("1:2 hello", &r)
+	if !ok || err == nil {
+		t.Errorf("error expected: ok=%v, err=%v", ok, err)
+	} else {
+		t.Log(err)
+	}
+}
+
+func TestConverters(t *testing.T) {
+	type record struct {
+		Addr  netip.Addr `rx:"addr"` // encoding.TextUnmarshaler
+		Name  upper      `rx:"name"` // Set(string) error
+		Count big.Int    `rx:"count"`
+		Ratio *big.Float `rx:"ratio"`
+		Frac  *big.Rat   `rx:"frac"`
+		Note  *string    `rx:"note"`
+	}
+
+	re := regexpstruct.MustCompile[record](`^(?P<addr>\S+) (?P<name>\w+) (?P<count>\d+) (?P<ratio>\S+) (?P<frac>\S+)(?: (?P<note>.*))?$`, "rx")
+
+	var r record
+	ok, err := re.FindStringStructErr("192.0.2.1 bob 123456789012345678901234567890 1.5 3/4", &r)
+	if !ok || err != nil {
+		t.Fatalf("ok=%v, err=%v", ok, err)
+	}
+
+	if r.Addr != netip.MustParseAddr("192.0.2.1") {
+		t.Errorf("Addr: %v", r.Addr)
+	}
+	if r.Name != "BOB" {
+		t.Errorf("Name: %q", r.Name)
+	}
+	if r.Count.String() != "123456789012345678901234567890" {
+		t.Errorf("Count: %v", &r.Count)
+	}
+	if r.Ratio == nil || r.Ratio.String() != "1.5" {
+		t.Errorf("Ratio: %v", r.Ratio)
+	}
+	if r.Frac == nil || r.Frac.RatString() != "3/4" {
+		t.Errorf("Frac: %v", r.Frac)
+	}
+	if r.Note != nil {
+		t.Errorf("Note: %q", *r.Note)
+	}
+
+	ok, err = re.FindStringStructErr("192.0.2.x bob 1 1.5 3/4 hello", &r)
+	if !ok || err == nil {
+		t.Fatalf("error expected: ok=%v, err=%v", ok, err)
+	}
+	t.Log(err)
+	if re.FindStringStruct("192.0.2.x bob 1 1.5 3/4 hello", &r) {
+		t.Error("FindStringStruct must fail on conversion error")
+	}
+
+	all := re.FindAllStringStruct("192.0.2.1 alice 1 2 1/2 hello", -1)
+	if len(all) != 1 || all[0].Note == nil || *all[0].Note != "hello" {
+		t.Errorf("FindAllStringStruct: %#v", all)
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	type record struct {
+		Flags  uint16  `rx:"flags,base=16"`
+		Size   int     `rx:"size,base=0"`
+		Amount float64 `rx:"amount,comma"`
+		Count  *int64  `rx:"count,comma"`
+		OK     bool    `rx:"ok"`
+	}
+
+	re := regexpstruct.MustCompile[record](`^(?P<flags>\w+) (?P<size>\w+) (?P<amount>[\d,.]+) (?P<count>[\d,]+) (?P<ok>\w+)$`, "rx")
+
+	var r record
+	ok, err := re.FindStringStructErr("ff0a 0o17 1,234,567.89 12,345 true", &r)
+	if !ok || err != nil {
+		t.Fatalf("ok=%v, err=%v", ok, err)
+	}
+	t.Logf("%+v", r)
+
+	if r.Flags != 0xff0a {
+		t.Errorf("Flags: %#x", r.Flags)
+	}
+	if r.Size != 017 {
+		t.Errorf("Size: %d", r.Size)
+	}
+	if r.Amount != 1234567.89 {
+		t.Errorf("Amount: %f", r.Amount)
+	}
+	if r.Count == nil || *r.Count != 12345 {
+		t.Errorf("Count: %v", r.Count)
+	}
+	if !r.OK {
+		t.Error("OK: false")
+	}
+
+	if _, err = re.FindStringStructErr("fffff 0 1 1 true", &r); err == nil {
+		t.Error("overflow expected")
+	} else {
+		t.Log(err)
+	}
+}
+
+func TestTagOptionsInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		f    func()
+	}{
+		{"base on string", func() {
+			type record struct {
+				S string `rx:"s,base=16"`
+			}
+			regexpstruct.MustCompile[record](`(?P<s>.*)`, "rx")
+		}},
+		{"invalid base", func() {
+			type record struct {
+				N int `rx:"n,base=37"`
+			}
+			regexpstruct.MustCompile[record](`(?P<n>.*)`, "rx")
+		}},
+		{"comma on string", func() {
+			type record struct {
+				S string `rx:"s,comma"`
+			}
+			regexpstruct.MustCompile[record](`(?P<s>.*)`, "rx")
+		}},
+		{"missing name", func() {
+			type record struct {
+				N int `rx:",comma"`
+			}
+			regexpstruct.MustCompile[record](`(?P<n>.*)`, "rx")
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mustPanic(t, tc.f)
+		})
+	}
+}
+
+func TestWithReset(t *testing.T) {
+	type pair struct {
+		K string `rx:"k"`
+		V *int   `rx:"v"`
+	}
+
+	const expr = `^(?P<k>\w+)(?:=(?P<v>\d+))?$`
+
+	var p pair
+	re := regexpstruct.MustCompile[pair](expr, "rx")
+	re.FindStringStruct("a=1", &p)
+	re.FindStringStruct("b", &p)
+	if p.K != "b" || p.V == nil || *p.V != 1 {
+		t.Errorf("without WithReset, V should be kept: %+v", p)
+	}
+
+	p = pair{}
+	re = regexpstruct.MustCompile[pair](expr, "rx", regexpstruct.WithReset())
+	re.FindStringStruct("a=1", &p)
+	if p.K != "a" || p.V == nil || *p.V != 1 {
+		t.Fatalf("unexpected: %+v", p)
+	}
+	re.FindStringStruct("b", &p)
+	if p.K != "b" || p.V != nil {
+		t.Errorf("V should be reset: %+v", p)
+	}
+	if re.FindStringStruct("", &p) || p.K != "b" {
+		t.Errorf("no match must not reset: %+v", p)
+	}
+}
+
+func TestFindAllLinesStruct(t *testing.T) {
+	type record struct {
+		N int8 `rx:"n"`
+	}
+
+	re := regexpstruct.MustCompile[record](`^n=(?P<n>\d+)$`, "rx")
+
+	lines := make([]string, 1000)
+	for i := range lines {
+		switch {
+		case i%3 == 0:
+			lines[i] = "skip"
+		case i%5 == 0:
+			lines[i] = "n=1000" // matches, but overflows int8
+		default:
+			lines[i] = fmt.Sprintf("n=%d", i%100)
+		}
+	}
+
+	for _, parallelism := range []int{0, 1, 4, 2000} {
+		values, index := re.FindAllLinesStruct(lines, parallelism)
+		if len(values) != 533 || len(index) != len(values) || cap(values) != len(values) {
+			t.Fatalf("parallelism %d: got %d values, %d indexes", parallelism, len(values), len(index))
+		}
+		prev := -1
+		for j, i := range index {
+			if i <= prev || i%3 == 0 || i%5 == 0 {
+				t.Fatalf("parallelism %d: unexpected index %d after %d", parallelism, i, prev)
+			}
+			if int(values[j].N) != i%100 {
+				t.Fatalf("parallelism %d: line %d: got %d", parallelism, i, values[j].N)
+			}
+			prev = i
+		}
+	}
+
+	if values, index := re.FindAllLinesStruct(nil, 0); values != nil || index != nil {
+		t.Errorf("nil expected: %v %v", values, index)
+	}
+}
+
+func TestWithFoldedNames(t *testing.T) {
+	type record struct {
+		UserID  string `rx:"user-id"`
+		Message string `rx:"Message"`
+		Address struct {
+			City string `rx:"city"`
+		} `rx:"address"`
+	}
+
+	const expr = `^(?P<USER_ID>\w+) (?P<Address__City>\w+) (?P<message>.*)$`
+	const s = `u42 Paris hello world`
+
+	var r record
+	re := regexpstruct.MustCompile[record](expr, "rx")
+	if !re.FindStringStruct(s, &r) {
+		t.Fatal("no match")
+	}
+	if r != (record{}) {
+		t.Errorf("no field should be bound without WithFoldedNames: %+v", r)
+	}
+
+	re = regexpstruct.MustCompile[record](expr, "rx", regexpstruct.WithFoldedNames())
+	if !re.FindStringStruct(s, &r) {
+		t.Fatal("no match")
+	}
+	if r.UserID != "u42" || r.Message != "hello world" || r.Address.City != "Paris" {
+		t.Errorf("unexpected: %+v", r)
+	}
+
+	type conflict struct {
+		A string `rx:"a_b"`
+		B string `rx:"A-B"`
+	}
+	mustPanic(t, func() {
+		regexpstruct.MustCompile[conflict](`(?P<a_b>.*)`, "rx", regexpstruct.WithFoldedNames())
+	})
+	if _, err := regexpstruct.CompileDynamic(`(?P<a_b>.*)`, "rx", reflect.TypeOf(conflict{}), regexpstruct.WithFoldedNames()); err == nil {
+		t.Error("CompileDynamic: error expected")
+	}
+}
+
+func TestAliases(t *testing.T) {
+	type record struct {
+		Level   string `rx:"level|lvl"`
+		Message string `rx:"msg|message|text"`
+		Source  struct {
+			File string `rx:"file"`
+		} `rx:"src|source"`
+	}
+
+	for _, tc := range []struct {
+		expr string
+		s    string
+	}{
+		{`^(?P<level>\w+) (?P<src__file>\S+): (?P<msg>.*)$`, `INFO main.go: hello`},
+		{`^(?P<source__file>\S+) \[(?P<lvl>\w+)\] (?P<text>.*)$`, `main.go [INFO] hello`},
+		{`^(?:(?P<level>\w+) (?P<source__file>\S+): |\[(?P<lvl>\w+)\] (?P<src__file>\S+) )(?P<message>.*)$`, `[INFO] main.go hello`},
+		{`^(?:(?P<level>\w+) (?P<source__file>\S+): |\[(?P<lvl>\w+)\] (?P<src__file>\S+) )(?P<message>.*)$`, `INFO main.go: hello`},
+	} {
+		for _, folded := range []bool{false, true} {
+			var opts []regexpstruct.Option
+			if folded {
+				opts = append(opts, regexpstruct.WithFoldedNames())
+			}
+			re := regexpstruct.MustCompile[record](tc.expr, "rx", opts...)
+			var r record
+			if !re.FindStringStruct(tc.s, &r) {
+				t.Errorf("%s (folded: %v): no match", tc.expr, folded)
+				continue
+			}
+			if r.Level != "INFO" || r.Message != "hello" || r.Source.File != "main.go" {
+				t.Errorf("%s (folded: %v): unexpected %+v", tc.expr, folded, r)
+			}
+		}
+	}
+}
+
+func TestRebind(t *testing.T) {
+	type pair struct {
+		K string `rx:"k"`
+		V string `rx:"v"`
+	}
+	type value struct {
+		N int `json:"v"`
+	}
+
+	re := regexpstruct.MustCompile[pair](`^(?P<k>\w+)=(?P<v>\d+)$`, "rx")
+	re2 := regexpstruct.Rebind[value](re, "json")
+
+	if re2.String() != re.String() {
+		t.Errorf("expression mismatch: %q", re2.String())
+	}
+
+	var p pair
+	var v value
+	if !re.FindStringStruct("a=42", &p) || !re2.FindStringStruct("a=42", &v) {
+		t.Fatal("no match")
+	}
+	if p != (pair{"a", "42"}) || v.N != 42 {
+		t.Errorf("unexpected: %+v %+v", p, v)
+	}
+}
+
+func TestCompileDynamic(t *testing.T) {
+	type pair struct {
+		K string `rx:"k"`
+		V int    `rx:"v"`
+	}
+
+	re := regexpstruct.MustCompileDynamic(`^(?P<k>\w+)=(?P<v>\w+)$`, "rx", reflect.TypeOf(pair{}))
+
+	var target any = new(pair)
+	ok, err := re.FindStringInto("a=42", target)
+	if !ok || err != nil {
+		t.Fatalf("ok=%v, err=%v", ok, err)
+	}
+	if *target.(*pair) != (pair{"a", 42}) {
+		t.Errorf("unexpected: %+v", target)
+	}
+
+	if ok, err = re.FindStringInto("a=b", target); !ok || err == nil {
+		t.Errorf("conversion error expected: ok=%v, err=%v", ok, err)
+	}
+
+	if sch := re.Schema(); sch.Type != "regexpstruct_test.pair" || len(sch.Fields) != 2 || sch.Fields[1].JSONType != "integer" {
+		t.Errorf("unexpected schema: %+v", sch)
+	}
+	re.Schema().Fields[0].Path[0] = "MUTATED"
+	if sch := re.Schema(); sch.Fields[0].Path[0] != "K" {
+		t.Errorf("Schema must return a copy: %+v", sch)
+	}
+
+	for _, bad := range []any{nil, pair{}, (*pair)(nil), new(string)} {
+		if _, err = re.FindStringInto("a=42", bad); err == nil {
+			t.Errorf("%#v: error expected", bad)
+		} else {
+			t.Log(err)
+		}
+	}
+}
+
+func ExampleRegexp_Schema() {
+	type record struct {
+		Level   string `rx:"level|lvl"`
+		Count   *int   `rx:"count,comma"`
+		Address struct {
+			City string `rx:"city"`
+		} `rx:"address"`
+	}
+
+	re := regexpstruct.MustCompile[record](`^(?:(?P<level>\w+)|\[(?P<lvl>\w+)\]) (?P<count>[\d,]+) (?P<address__city>.*)$`, "rx")
+
+	b, _ := json.MarshalIndent(re.Schema(), "", "  ")
+	fmt.Println(string(b))
+
+	// Output:
+	// {
+	//   "type": "regexpstruct_test.record",
+	//   "fields": [
+	//     {
+	//       "path": [
+	//         "Level"
+	//       ],
+	//       "groups": [
+	//         "level",
+	//         "lvl"
+	//       ],
+	//       "goType": "string",
+	//       "jsonType": "string"
+	//     },
+	//     {
+	//       "path": [
+	//         "Count"
+	//       ],
+	//       "groups": [
+	//         "count"
+	//       ],
+	//       "goType": "*int",
+	//       "jsonType": "integer",
+	//       "base": 10,
+	//       "comma": true
+	//     },
+	//     {
+	//       "path": [
+	//         "Address",
+	//         "City"
+	//       ],
+	//       "groups": [
+	//         "address__city"
+	//       ],
+	//       "goType": "string",
+	//       "jsonType": "string"
+	//     }
+	//   ]
+	// }
+}
+
+func TestNotParticipating(t *testing.T) {
+	type pair struct {
+		A string `rx:"a"`
+		B string `rx:"b"`
+		N int    `rx:"n"`
+	}
+
+	re := regexpstruct.MustCompile[pair](`^(?P<a>\w+)(?:=(?P<b>\w+))?(?:#(?P<n>\d+))?$`, "rx")
+
+	var p pair
+	if !re.FindStringStruct("x=y#1", &p) || p != (pair{"x", "y", 1}) {
+		t.Fatalf("unexpected: %+v", p)
+	}
+	if !re.FindStringStruct("z", &p) {
+		t.Fatal("no match")
+	}
+	if p != (pair{A: "z"}) {
+		t.Errorf("fields of submatches that didn't participate must be reset: %+v", p)
+	}
+
+	// Flat string-only struct
+	type flat struct {
+		A string `rx:"a"`
+		B string `rx:"b"`
+	}
+	re2 := regexpstruct.MustCompile[flat](`(?P<a>\w+)(?:=(?P<b>\w+))?`, "rx")
+	var f flat
+	re2.FindStringStruct("x=y", &f)
+	re2.FindStringStruct("z", &f)
+	if f != (flat{A: "z"}) {
+		t.Errorf("fields of submatches that didn't participate must be reset: %+v", f)
+	}
+}
+
+// Address is exported to be embedded as a pointer.
+type Address struct {
+	City    string `rx:"city"`
+	Country string `rx:"country"`
+}
+
+func TestEmbeddedPointer(t *testing.T) {
+	type person struct {
+		Name string `rx:"name"`
+		*Address
+	}
+
+	re := regexpstruct.MustCompile[person](`^(?P<name>.*) / (?P<city>.*) / (?P<country>.*)$`, "rx")
+
+	var p person
+	if !re.FindStringStruct(`Leonardo da Vinci / Florence / Italia`, &p) {
+		t.Fatal("no match")
+	}
+	if p.Name != "Leonardo da Vinci" || p.Address == nil || *p.Address != (Address{"Florence", "Italia"}) {
+		t.Errorf("unexpected: %+v", p)
+	}
+}
+
+func TestUnexported(t *testing.T) {
+	type onlyString struct {
+		a string `rx:"a"`
+	}
+	type withInt struct {
+		a string `rx:"a"`
+		N int    `rx:"n"`
+	}
+	type address struct {
+		City string `rx:"city"`
+	}
+	type unexportedPtr struct {
+		*address
+	}
+
+	for _, tc := range []struct {
+		name string
+		f    func()
+	}{
+		{"onlyString", func() { regexpstruct.MustCompile[onlyString](`(?P<a>\w+)`, "rx") }},
+		{"withInt", func() { regexpstruct.MustCompile[withInt](`(?P<a>\w+) (?P<n>\d+)`, "rx") }},
+		{"unexportedPtr", func() { regexpstruct.MustCompile[unexportedPtr](`(?P<city>\w+)`, "rx") }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mustPanic(t, tc.f)
+		})
+	}
+
+	// An unexported field that is not bound is ignored
+	regexpstruct.MustCompile[withInt](`(?P<n>\d+)`, "rx")
+}
+
+func TestCompileDynamicInvalid(t *testing.T) {
+	type untagged struct {
+		A string
+	}
+	type badOption struct {
+		S string `rx:"s,base=16"`
+	}
+
+	for _, typ := range []reflect.Type{
+		nil,
+		reflect.TypeOf(0),
+		reflect.TypeOf(&untagged{}),
+		reflect.TypeOf(untagged{}),
+		reflect.TypeOf(badOption{}),
+	} {
+		re, err := regexpstruct.CompileDynamic(`(?P<s>.*)`, "rx", typ)
+		if err == nil || re != nil {
+			t.Errorf("%v: error expected", typ)
+		} else {
+			t.Log(err)
+		}
+	}
+}
+
+func TestNotParticipatingPointer(t *testing.T) {
+	type address struct {
+		City    string `rx:"city"`
+		Country string `rx:"country"`
+	}
+	type person struct {
+		Name    string   `rx:"name"`
+		Address *address `rx:"address"`
+	}
+
+	re := regexpstruct.MustCompile[person](`^(?P<name>\w+)(?: / (?P<address__city>\w+) / (?P<address__country>\w+))?$`, "rx", regexpstruct.WithReset())
+
+	var p person
+	if !re.FindStringStruct("Leonardo", &p) {
+		t.Fatal("no match")
+	}
+	if p.Address != nil {
+		t.Errorf("Address must not be allocated: %+v", *p.Address)
+	}
+
+	if !re.FindStringStruct("Leonardo / Florence / Italia", &p) || p.Address == nil || p.Address.City != "Florence" {
+		t.Fatalf("unexpected: %+v", p)
+	}
+	if !re.FindStringStruct("Michelangelo", &p) {
+		t.Fatal("no match")
+	}
+	if p.Address != nil {
+		t.Errorf("Address must be reset: %+v", *p.Address)
+	}
+
+	// Without WithReset, an allocated pointer is reused and its fields reset
+	re = regexpstruct.MustCompile[person](re.String(), "rx")
+	re.FindStringStruct("Leonardo / Florence / Italia", &p)
+	re.FindStringStruct("Michelangelo", &p)
+	if p.Address == nil || *p.Address != (address{}) {
+		t.Errorf("unexpected: %+v", p)
+	}
+}
+
+func TestForeignTag(t *testing.T) {
+	type record struct {
+		K    string `json:"k,omitempty"`
+		V    int    `json:"v,string,omitempty"`
+		Note string `json:",omitempty"`
+	}
+
+	re := regexpstruct.MustCompile[record](`^(?P<k>\w+)=(?P<v>\d+)$`, "json")
+
+	var r record
+	if !re.FindStringStruct("a=42", &r) {
+		t.Fatal("no match")
+	}
+	if r != (record{K: "a", V: 42}) {
+		t.Errorf("unexpected: %+v", r)
+	}
+}
+
+// level is an integer with a Set method.
+type level int
+
+func (l *level) Set(s string) error {
+	switch s {
+	case "INFO":
+		*l = 1
+	case "WARN":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", s)
+	}
+	return nil
+}
+
+func TestSchemaJSONType(t *testing.T) {
+	type record struct {
+		Level level      `rx:"level"`
+		Count *big.Int   `rx:"count"`
+		Ratio big.Float  `rx:"ratio"`
+		Addr  netip.Addr `rx:"addr"`
+		Pos   point      `rx:"pos"`
+		Flags uint8      `rx:"flags,base=16"`
+	}
+
+	re := regexpstruct.MustCompile[record](`(?P<level>\w+) (?P<count>\d+) (?P<ratio>\S+) (?P<addr>\S+) (?P<pos>\S+) (?P<flags>\w+)`, "rx")
+
+	want := map[string]string{
+		"Level": "integer",
+		"Count": "number",
+		"Ratio": "string",
+		"Addr":  "string",
+		"Pos":   "object",
+		"Flags": "integer",
+	}
+	sch := re.Schema()
+	for _, f := range sch.Fields {
+		if f.JSONType != want[f.Path[0]] {
+			t.Errorf("%s: got %q, expected %q", f.Path[0], f.JSONType, want[f.Path[0]])
+		}
+		if f.Path[0] == "Flags" && (f.Base == nil || *f.Base != 16) {
+			t.Errorf("Flags: base 16 expected")
+		}
+	}
+	if len(sch.Fields) != len(want) {
+		t.Errorf("got %d fields", len(sch.Fields))
+	}
+
+	// Check consistency with encoding/json
+	var r record
+	if !re.FindStringStruct("WARN 12 1.5 192.0.2.1 1:2 ff", &r) {
+		t.Fatal("no match")
+	}
+	b, err := json.Marshal(&r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(string(b))
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 Olivier Mengué
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package regexpstruct
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Schema describes the values produced by a [Regexp] or a [DynamicRegexp].
+// It is designed to be encoded as JSON, for consumers that can't import the
+// struct type.
+type Schema struct {
+	Type   string        `json:"type"`   // Go type of the struct
+	Fields []FieldSchema `json:"fields"` // in the order of the submatches
+}
+
+// FieldSchema describes a struct field bound to submatches.
+type FieldSchema struct {
+	Path   []string `json:"path"`   // names of the Go fields from the struct
+	Groups []string `json:"groups"` // names of the bound submatches
+	GoType string   `json:"goType"` // Go type of the field
+	// JSONType is the type of the field value encoded by [encoding/json]:
+	// "string", "integer", "number", "boolean", "object", "array", or "" if
+	// unknown.
+	JSONType string `json:"jsonType"`
+	// Base is the base for parsing the text of integer fields (tag option
+	// base=N, 0 meaning implied by the prefix), nil for other fields.
+	Base *int `json:"base,omitempty"`
+	// Comma is true if commas are removed from the text (tag option comma).
+	Comma bool `json:"comma,omitempty"`
+}
+
+// Schema describes the fields of T bound to the submatches of re.
+func (re *Regexp[T]) Schema() Schema {
+	return re.binding.schema()
+}
+
+// Schema describes the fields of [DynamicRegexp.Type] bound to the submatches
+// of re.
+func (re *DynamicRegexp) Schema() Schema {
+	return re.binding.schema()
+}
+
+func (b *binding) schema() Schema {
+	sch := Schema{
+		Type:   b.typ.String(),
+		Fields: make([]FieldSchema, 0, len(b.captures)),
+	}
+	index := make(map[string]int, len(b.captures)) // Fields index by path
+	for _, c := range b.captures {
+		path := strings.Join(c.path, ".")
+		if i, seen := index[path]; seen {
+			sch.Fields[i].Groups = append(sch.Fields[i].Groups, c.name)
+			continue
+		}
+		index[path] = len(sch.Fields)
+		fs := FieldSchema{
+			Path:     slices.Clone(c.path),
+			Groups:   []string{c.name},
+			GoType:   c.typ.String(),
+			JSONType: jsonType(c.typ),
+			Comma:    c.opts.comma,
+		}
+		if t := c.typ; isInteger(t) || (t.Kind() == reflect.Ptr && isInteger(t.Elem())) {
+			base := c.opts.base
+			fs.Base = &base
+		}
+		sch.Fields = append(sch.Fields, fs)
+	}
+	return sch
+}
+
+var (
+	typeJSONMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	typeTextMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// jsonType returns the JSON type of the non-null values of type t encoded by
+// [encoding/json], or "" if unknown.
+func jsonType(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	pt := reflect.PointerTo(t)
+	switch {
+	case pt.Implements(typeJSONMarshaler):
+		// Look at the encoding of the zero value
+		b, err := json.Marshal(reflect.New(t).Interface())
+		if err != nil || len(b) == 0 {
+			return ""
+		}
+		switch b[0] {
+		case '"':
+			return "string"
+		case 't', 'f':
+			return "boolean"
+		case '{':
+			return "object"
+		case '[':
+			return "array"
+		case 'n':
+			return ""
+		}
+		return "number"
+	case pt.Implements(typeTextMarshaler):
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return ""
+}